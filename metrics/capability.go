@@ -0,0 +1,57 @@
+package metrics
+
+// Capabilities is a description of metrics reporting capabilities.
+type Capabilities interface {
+	// Reporting returns whether the reporter has the ability to actively
+	// report.
+	Reporting() bool
+
+	// Tagging returns whether the reporter has the capability for tagged
+	// metrics.
+	Tagging() bool
+
+	// Exemplars returns whether the reporter can attach OpenMetrics
+	// exemplars (e.g. a trace ID) to counter increments and histogram
+	// observations.
+	Exemplars() bool
+}
+
+type capabilities struct {
+	reporting bool
+	tagging   bool
+	exemplars bool
+}
+
+func (c capabilities) Reporting() bool {
+	return c.reporting
+}
+
+func (c capabilities) Tagging() bool {
+	return c.tagging
+}
+
+func (c capabilities) Exemplars() bool {
+	return c.exemplars
+}
+
+// NewCapabilities returns a Capabilities with the given reporting, tagging
+// and exemplar support, for reporters outside this package that need to
+// construct one of their own.
+func NewCapabilities(reporting, tagging, exemplars bool) Capabilities {
+	return capabilities{
+		reporting: reporting,
+		tagging:   tagging,
+		exemplars: exemplars,
+	}
+}
+
+var (
+	capabilitiesReportingNoTagging = capabilities{
+		reporting: true,
+		tagging:   false,
+	}
+	capabilitiesNone = capabilities{
+		reporting: false,
+		tagging:   false,
+	}
+)
@@ -1,43 +1,44 @@
 package metrics
 
+import (
+	"sync"
+)
+
 // ObjectPool is an minimalistic object pool to avoid
 // any circular dependencies on any other object pool.
+//
+// It is backed directly by a sync.Pool, which already shards its free
+// list per-P internally; wrapping it in an additional hand-rolled shard
+// layer would only add a second, redundant P lookup per Get/Put.
 type ObjectPool struct {
-	values chan interface{}
-	alloc  func() interface{}
+	pool sync.Pool
+	size int
 }
 
-// NewObjectPool creates a new pool.
+// NewObjectPool creates a new pool. size controls how many objects are
+// pre-warmed; the pool is otherwise unbounded; like sync.Pool, objects may
+// be reclaimed by the garbage collector under memory pressure.
 func NewObjectPool(size int) *ObjectPool {
 	return &ObjectPool{
-		values: make(chan interface{}, size),
+		size: size,
 	}
 }
 
 // Init initializes the object pool.
 func (p *ObjectPool) Init(alloc func() interface{}) {
-	p.alloc = alloc
+	p.pool.New = alloc
 
-	for i := 0; i < cap(p.values); i++ {
-		p.values <- p.alloc()
+	for i := 0; i < p.size; i++ {
+		p.pool.Put(alloc())
 	}
 }
 
 // Get gets an object from the pool.
 func (p *ObjectPool) Get() interface{} {
-	var v interface{}
-	select {
-	case v = <-p.values:
-	default:
-		v = p.alloc()
-	}
-	return v
+	return p.pool.Get()
 }
 
 // Put puts an object back to the pool.
 func (p *ObjectPool) Put(obj interface{}) {
-	select {
-	case p.values <- obj:
-	default:
-	}
+	p.pool.Put(obj)
 }
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"testing"
+)
+
+func BenchmarkObjectPoolGetPutSequential(b *testing.B) {
+	pool := NewObjectPool(1024)
+	pool.Init(func() interface{} {
+		return make([]byte, 0, 32)
+	})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v := pool.Get()
+		pool.Put(v)
+	}
+}
+
+func BenchmarkObjectPoolGetPutParallel(b *testing.B) {
+	pool := NewObjectPool(1024)
+	pool.Init(func() interface{} {
+		return make([]byte, 0, 32)
+	})
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			v := pool.Get()
+			pool.Put(v)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/gottingen/kmetrics/metrics"
+	prom "github.com/m3db/prometheus_client_golang/prometheus"
+)
+
+// cachedCounter adapts a prom.Counter to metrics.CachedCount, additionally
+// implementing metrics.CachedCountExemplar so callers that have confirmed
+// Capabilities().Exemplars() can attach a trace ID (or other exemplar
+// labels) to the increment.
+type cachedCounter struct {
+	counter prom.Counter
+}
+
+func (c *cachedCounter) ReportCount(value int64) {
+	c.counter.Add(float64(value))
+}
+
+func (c *cachedCounter) ReportCountWithExemplar(value int64, labels map[string]string) {
+	incCounterWithExemplar(c.counter, value, labels)
+}
+
+// cachedGauge adapts a prom.Gauge to metrics.CachedGauge.
+type cachedGauge struct {
+	gauge prom.Gauge
+}
+
+func (g *cachedGauge) ReportGauge(value float64) {
+	g.gauge.Set(value)
+}
+
+// cachedTimer adapts a prom.Observer (either a histogram or a summary,
+// depending on the reporter's configured timer type) to metrics.CachedTimer.
+type cachedTimer struct {
+	observer prom.Observer
+}
+
+func (t *cachedTimer) ReportTimer(interval time.Duration) {
+	t.observer.Observe(interval.Seconds())
+}
+
+// cachedHistogram adapts a prom.Observer to metrics.CachedHistogram.
+// Prometheus histograms and summaries bucket observations automatically
+// from the raw value, so each bucket handed out just observes its upper
+// bound against the same underlying observer the requested number of
+// times.
+type cachedHistogram struct {
+	observer prom.Observer
+}
+
+func (h *cachedHistogram) ValueBucket(
+	bucketLowerBound, bucketUpperBound float64,
+) metrics.CachedHistogramBucket {
+	return &cachedHistogramBucket{observer: h.observer, upperBound: bucketUpperBound}
+}
+
+func (h *cachedHistogram) DurationBucket(
+	bucketLowerBound, bucketUpperBound time.Duration,
+) metrics.CachedHistogramBucket {
+	return &cachedHistogramBucket{observer: h.observer, upperBound: bucketUpperBound.Seconds()}
+}
+
+// cachedHistogramBucket adapts a prom.Observer to
+// metrics.CachedHistogramBucket, additionally implementing
+// metrics.CachedHistogramBucketExemplar so callers that have confirmed
+// Capabilities().Exemplars() can attach exemplar labels to each sample.
+type cachedHistogramBucket struct {
+	observer   prom.Observer
+	upperBound float64
+}
+
+func (b *cachedHistogramBucket) ReportSamples(value int64) {
+	for i := int64(0); i < value; i++ {
+		b.observer.Observe(b.upperBound)
+	}
+}
+
+func (b *cachedHistogramBucket) ReportSamplesWithExemplar(value int64, labels map[string]string) {
+	for i := int64(0); i < value; i++ {
+		observeWithExemplar(b.observer, b.upperBound, labels)
+	}
+}
@@ -0,0 +1,67 @@
+package prometheus
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+
+	"github.com/gottingen/kmetrics/metrics"
+)
+
+// gzipWriterPool reuses gzip.Writer values across requests so that
+// compressing the exposition output of a large registry doesn't allocate a
+// new compressor (and its window buffers) per scrape.
+var gzipWriterPool = newGzipWriterPool()
+
+func newGzipWriterPool() *metrics.ObjectPool {
+	pool := metrics.NewObjectPool(16)
+	pool.Init(func() interface{} {
+		return gzip.NewWriter(nil)
+	})
+	return pool
+}
+
+// compressHandler wraps h so that, when the request's Accept-Encoding
+// offers gzip, the response body is streamed through a pooled gzip.Writer
+// and Content-Encoding: gzip is set. Responses are otherwise passed through
+// unmodified, preserving whatever Content-Type negotiation h performs.
+func compressHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !acceptsGzip(r) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		gw := gzipWriterPool.Get().(*gzip.Writer)
+		gw.Reset(w)
+		defer func() {
+			_ = gw.Close()
+			gzipWriterPool.Put(gw)
+		}()
+
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Add("Vary", "Accept-Encoding")
+		h.ServeHTTP(&gzipResponseWriter{ResponseWriter: w, writer: gw}, r)
+	})
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		if strings.EqualFold(strings.TrimSpace(encoding), "gzip") {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipResponseWriter routes body writes through the pooled gzip.Writer while
+// leaving header/status handling to the wrapped http.ResponseWriter so
+// Content-Type negotiation performed upstream is unaffected.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	writer *gzip.Writer
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	return w.writer.Write(b)
+}
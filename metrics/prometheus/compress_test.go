@@ -0,0 +1,76 @@
+package prometheus
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressHandlerHonorsAcceptEncoding(t *testing.T) {
+	body := []byte("# HELP test_metric test\n# TYPE test_metric counter\ntest_metric 1\n")
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write(body)
+	})
+	handler := compressHandler(inner)
+
+	t.Run("gzip offered", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		require.Equal(t, "text/plain; version=0.0.4", rec.Header().Get("Content-Type"))
+
+		gr, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+		decoded, err := ioutil.ReadAll(gr)
+		require.NoError(t, err)
+		require.Equal(t, body, decoded)
+	})
+
+	t.Run("gzip not offered", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		require.Empty(t, rec.Header().Get("Content-Encoding"))
+		require.Equal(t, body, rec.Body.Bytes())
+	})
+}
+
+func benchmarkRegistryHandler(b *testing.B, seriesCount int, compressed bool) {
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < seriesCount; i++ {
+			fmt.Fprintf(w, "test_metric{id=\"%d\"} %d\n", i, i)
+		}
+	})
+
+	handler := http.Handler(inner)
+	if compressed {
+		handler = compressHandler(inner)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+func BenchmarkHTTPHandlerUncompressed10kSeries(b *testing.B) {
+	benchmarkRegistryHandler(b, 10000, false)
+}
+
+func BenchmarkHTTPHandlerCompressed10kSeries(b *testing.B) {
+	benchmarkRegistryHandler(b, 10000, true)
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/gottingen/kmetrics/metrics"
 	prom "github.com/m3db/prometheus_client_golang/prometheus"
 )
 
@@ -21,6 +22,7 @@ type Configuration struct {
 	ListenAddress string `yaml:"listenAddress"`
 
 	// TimerType is the default Prometheus type to use for metric timers.
+	// One of "summary" or "histogram".
 	TimerType string `yaml:"timerType"`
 
 	// DefaultHistogramBuckets if specified will set the default histogram
@@ -35,6 +37,17 @@ type Configuration struct {
 	// on the specified listen address or registering a metric with the
 	// Prometheus. By default the registerer will panic.
 	OnError string `yaml:"onError"`
+
+	// RemoteWrite if specified will, in addition to (or instead of, if
+	// ListenAddress is unset) exposing a scrape endpoint, periodically push
+	// accumulated metrics to a Prometheus remote-write endpoint. This is
+	// intended for short-lived processes that cannot be scraped.
+	RemoteWrite *RemoteWriteConfiguration `yaml:"remoteWrite"`
+
+	// CompressResponses if set will gzip-encode the scrape endpoint's
+	// response body when the scraper's Accept-Encoding offers gzip. This
+	// can substantially reduce payload size on large registries.
+	CompressResponses bool `yaml:"compressResponses"`
 }
 
 // HistogramObjective is a Prometheus histogram bucket.
@@ -115,6 +128,8 @@ func (c Configuration) NewReporter(
 		opts.DefaultSummaryObjectives = values
 	}
 
+	opts.CompressResponses = c.CompressResponses
+
 	reporter := NewReporter(opts)
 
 	path := "/metrics"
@@ -122,11 +137,13 @@ func (c Configuration) NewReporter(
 		path = handlerPath
 	}
 
+	handler := reporter.HTTPHandler()
+
 	if addr := strings.TrimSpace(c.ListenAddress); addr == "" {
-		http.Handle(path, reporter.HTTPHandler())
+		http.Handle(path, handler)
 	} else {
 		mux := http.NewServeMux()
-		mux.Handle(path, reporter.HTTPHandler())
+		mux.Handle(path, handler)
 		go func() {
 			if err := http.ListenAndServe(addr, mux); err != nil {
 				opts.OnRegisterError(err)
@@ -134,5 +151,16 @@ func (c Configuration) NewReporter(
 		}()
 	}
 
+	if c.RemoteWrite != nil {
+		var gatherer prom.Gatherer = configOpts.Registry
+		if configOpts.Registry == nil {
+			gatherer = prom.DefaultGatherer
+		}
+
+		sanitizer := metrics.NewSanitizer(DefaultSanitizerOpts)
+		client := newRemoteWriteClient(*c.RemoteWrite, gatherer, sanitizer, opts.OnRegisterError)
+		client.Start()
+	}
+
 	return reporter, nil
 }
@@ -0,0 +1,44 @@
+package prometheus
+
+import (
+	prom "github.com/m3db/prometheus_client_golang/prometheus"
+)
+
+// exemplarLabels converts exemplar tag pairs (e.g. a trace ID) into the
+// prom.Labels shape expected by client_golang's exemplar APIs.
+func exemplarLabels(labels map[string]string) prom.Labels {
+	if len(labels) == 0 {
+		return nil
+	}
+	out := make(prom.Labels, len(labels))
+	for k, v := range labels {
+		out[k] = v
+	}
+	return out
+}
+
+// incCounterWithExemplar increments a Prometheus counter, attaching an
+// OpenMetrics exemplar when the underlying metric supports it
+// (client_golang's ExemplarAdder). Callers should only reach this path
+// once Capabilities.Exemplars() has confirmed the reporter supports it;
+// when the concrete metric doesn't implement ExemplarAdder this falls
+// back to a plain increment.
+func incCounterWithExemplar(c prom.Counter, delta int64, labels map[string]string) {
+	if adder, ok := c.(prom.ExemplarAdder); ok {
+		adder.AddWithExemplar(float64(delta), exemplarLabels(labels))
+		return
+	}
+	c.Add(float64(delta))
+}
+
+// observeWithExemplar records a histogram observation, attaching an
+// OpenMetrics exemplar when the underlying metric supports it
+// (client_golang's ExemplarObserver). Falls back to a plain observation
+// when the concrete metric doesn't implement ExemplarObserver.
+func observeWithExemplar(o prom.Observer, value float64, labels map[string]string) {
+	if observer, ok := o.(prom.ExemplarObserver); ok {
+		observer.ObserveWithExemplar(value, exemplarLabels(labels))
+		return
+	}
+	o.Observe(value)
+}
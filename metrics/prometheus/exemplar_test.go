@@ -0,0 +1,36 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	prom "github.com/m3db/prometheus_client_golang/prometheus"
+	dto "github.com/m3db/prometheus_client_model/go"
+)
+
+func TestIncCounterWithExemplarFallsBackWithoutExemplarSupport(t *testing.T) {
+	counter := prom.NewCounter(prom.CounterOpts{Name: "test_counter", Help: "test"})
+
+	incCounterWithExemplar(counter, 3, map[string]string{"trace_id": "abc123"})
+
+	ch := make(chan prom.Metric, 1)
+	counter.Collect(ch)
+	m := <-ch
+
+	pb := &dto.Metric{}
+	require.NoError(t, m.Write(pb))
+	require.Equal(t, float64(3), pb.GetCounter().GetValue())
+}
+
+func TestObserveWithExemplarFallsBackWithoutExemplarSupport(t *testing.T) {
+	histogram := prom.NewHistogram(prom.HistogramOpts{
+		Name:    "test_histogram",
+		Help:    "test",
+		Buckets: []float64{1, 2, 5},
+	})
+
+	require.NotPanics(t, func() {
+		observeWithExemplar(histogram, 1.5, map[string]string{"trace_id": "abc123"})
+	})
+}
@@ -0,0 +1,369 @@
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/gottingen/kmetrics/metrics"
+	prom "github.com/m3db/prometheus_client_golang/prometheus"
+	dto "github.com/m3db/prometheus_client_model/go"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+const (
+	remoteWriteVersionHeader = "X-Prometheus-Remote-Write-Version"
+	remoteWriteVersion       = "0.1.0"
+
+	defaultRemoteWriteFlushInterval = 10 * time.Second
+	defaultRemoteWriteQueueSize     = 128
+	defaultRemoteWriteMaxRetries    = 5
+	defaultRemoteWriteRetryInterval = 500 * time.Millisecond
+)
+
+// RemoteWriteConfiguration configures pushing accumulated metrics to a
+// Prometheus remote-write endpoint on an interval, for processes that are
+// too short-lived to be scraped.
+type RemoteWriteConfiguration struct {
+	// URL is the remote-write endpoint to POST samples to.
+	URL string `yaml:"url"`
+
+	// FlushInterval is how often accumulated samples are pushed. Defaults
+	// to 10s.
+	FlushInterval time.Duration `yaml:"flushInterval"`
+
+	// QueueSize bounds the number of pending pushes kept in memory while a
+	// push is retrying; once full, each newly gathered push is dropped (and
+	// reported via the error callback) so the flush loop never blocks.
+	// Defaults to 128.
+	QueueSize int `yaml:"queueSize"`
+
+	// BearerToken, if set, is sent as an "Authorization: Bearer" header.
+	BearerToken string `yaml:"bearerToken"`
+
+	// BasicAuth, if set, is sent as HTTP basic auth credentials. It is not
+	// sent when BearerToken is also set.
+	BasicAuth *RemoteWriteBasicAuth `yaml:"basicAuth"`
+}
+
+// RemoteWriteBasicAuth is a username/password pair for remote-write basic auth.
+type RemoteWriteBasicAuth struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// remoteWriteClient periodically gathers from a Prometheus registry and
+// pushes the accumulated samples to a remote-write endpoint.
+type remoteWriteClient struct {
+	url           string
+	httpClient    *http.Client
+	bearerToken   string
+	basicAuth     *RemoteWriteBasicAuth
+	gatherer      prom.Gatherer
+	sanitizer     metrics.Sanitizer
+	onError       func(err error)
+	flushInterval time.Duration
+	queue         chan *prompb.WriteRequest
+	maxRetries    int
+	retryInterval time.Duration
+	doneCh        chan struct{}
+}
+
+func newRemoteWriteClient(
+	cfg RemoteWriteConfiguration,
+	gatherer prom.Gatherer,
+	sanitizer metrics.Sanitizer,
+	onError func(err error),
+) *remoteWriteClient {
+	flushInterval := cfg.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = defaultRemoteWriteFlushInterval
+	}
+
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultRemoteWriteQueueSize
+	}
+
+	return &remoteWriteClient{
+		url:           cfg.URL,
+		httpClient:    &http.Client{Timeout: flushInterval},
+		bearerToken:   cfg.BearerToken,
+		basicAuth:     cfg.BasicAuth,
+		gatherer:      gatherer,
+		sanitizer:     sanitizer,
+		onError:       onError,
+		flushInterval: flushInterval,
+		queue:         make(chan *prompb.WriteRequest, queueSize),
+		maxRetries:    defaultRemoteWriteMaxRetries,
+		retryInterval: defaultRemoteWriteRetryInterval,
+		doneCh:        make(chan struct{}),
+	}
+}
+
+// Start begins the periodic gather-and-push loop and the background sender.
+// Both run until Stop is called.
+func (c *remoteWriteClient) Start() {
+	go c.sendLoop()
+	go c.flushLoop()
+}
+
+// Stop halts the gather-and-push loop and the background sender.
+func (c *remoteWriteClient) Stop() {
+	close(c.doneCh)
+}
+
+func (c *remoteWriteClient) flushLoop() {
+	ticker := time.NewTicker(c.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.enqueue()
+		case <-c.doneCh:
+			return
+		}
+	}
+}
+
+// enqueue gathers the current state of the registry and hands it to the
+// background sender, never blocking the flush loop: if the bounded queue is
+// full the write is dropped and reported via onError.
+func (c *remoteWriteClient) enqueue() {
+	families, err := c.gatherer.Gather()
+	if err != nil {
+		c.onError(fmt.Errorf("remote write gather: %w", err))
+		return
+	}
+
+	req := &prompb.WriteRequest{
+		Timeseries: c.timeseries(families),
+	}
+
+	select {
+	case c.queue <- req:
+	default:
+		c.onError(fmt.Errorf("remote write queue full, dropping %d series", len(req.Timeseries)))
+	}
+}
+
+func (c *remoteWriteClient) sendLoop() {
+	for {
+		select {
+		case req := <-c.queue:
+			if err := c.pushWithRetry(req); err != nil {
+				c.onError(fmt.Errorf("remote write push: %w", err))
+			}
+		case <-c.doneCh:
+			return
+		}
+	}
+}
+
+func (c *remoteWriteClient) pushWithRetry(req *prompb.WriteRequest) error {
+	var err error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(c.retryInterval * time.Duration(1<<uint(attempt-1)))
+		}
+
+		var retryable bool
+		retryable, err = c.push(req)
+		if err == nil {
+			return nil
+		}
+		if !retryable {
+			return err
+		}
+	}
+	return err
+}
+
+func (c *remoteWriteClient) push(req *prompb.WriteRequest) (retryable bool, err error) {
+	data, err := proto.Marshal(req)
+	if err != nil {
+		return false, err
+	}
+
+	compressed := snappy.Encode(nil, data)
+
+	httpReq, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(compressed))
+	if err != nil {
+		return false, err
+	}
+	httpReq.Header.Set("Content-Encoding", "snappy")
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	httpReq.Header.Set(remoteWriteVersionHeader, remoteWriteVersion)
+	if c.bearerToken != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.bearerToken)
+	} else if c.basicAuth != nil {
+		httpReq.SetBasicAuth(c.basicAuth.Username, c.basicAuth.Password)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return true, err
+	}
+	defer func() {
+		_, _ = ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests {
+		return true, fmt.Errorf("remote write endpoint returned %s", resp.Status)
+	}
+	if resp.StatusCode/100 != 2 {
+		return false, fmt.Errorf("remote write endpoint returned %s", resp.Status)
+	}
+	return false, nil
+}
+
+// timeseries converts gathered metric families into remote-write
+// TimeSeries, sanitizing labels the same way the scrape exposition does.
+// Histograms and summaries expand into the same `_sum`/`_count`/`_bucket`
+// and `quantile=` series that the text exposition format produces, since
+// remote-write receivers (Cortex, Mimir, Thanos) expect that shape rather
+// than a single collapsed sample.
+func (c *remoteWriteClient) timeseries(families []*dto.MetricFamily) []prompb.TimeSeries {
+	nowMs := time.Now().UnixNano() / int64(time.Millisecond)
+
+	var result []prompb.TimeSeries
+	for _, family := range families {
+		name := c.sanitizer.Name(family.GetName())
+		for _, m := range family.GetMetric() {
+			base := c.baseLabels(m)
+
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				result = append(result, c.sample(name, base, m.GetCounter().GetValue(), nowMs))
+			case dto.MetricType_GAUGE:
+				result = append(result, c.sample(name, base, m.GetGauge().GetValue(), nowMs))
+			case dto.MetricType_SUMMARY:
+				result = append(result, c.summarySeries(name, base, m.GetSummary(), nowMs)...)
+			case dto.MetricType_HISTOGRAM:
+				result = append(result, c.histogramSeries(name, base, m.GetHistogram(), nowMs)...)
+			}
+		}
+	}
+	return result
+}
+
+// baseLabels sanitizes a metric's tag pairs, excluding the `__name__`
+// label so callers can append whichever name suffix applies.
+func (c *remoteWriteClient) baseLabels(m *dto.Metric) []prompb.Label {
+	labels := make([]prompb.Label, 0, len(m.GetLabel()))
+	for _, pair := range m.GetLabel() {
+		labels = append(labels, prompb.Label{
+			Name:  c.sanitizer.Key(pair.GetName()),
+			Value: c.sanitizer.Value(pair.GetValue()),
+		})
+	}
+	return labels
+}
+
+// sample builds a single TimeSeries for name{base...} = value.
+func (c *remoteWriteClient) sample(
+	name string,
+	base []prompb.Label,
+	value float64,
+	timestampMs int64,
+) prompb.TimeSeries {
+	labels := make([]prompb.Label, 0, len(base)+1)
+	labels = append(labels, prompb.Label{Name: "__name__", Value: name})
+	labels = append(labels, base...)
+	return prompb.TimeSeries{
+		Labels:  labels,
+		Samples: []prompb.Sample{{Value: value, Timestamp: timestampMs}},
+	}
+}
+
+// summarySeries expands a summary into its `_sum`, `_count` and
+// `quantile=`-labeled series.
+func (c *remoteWriteClient) summarySeries(
+	name string,
+	base []prompb.Label,
+	s *dto.Summary,
+	timestampMs int64,
+) []prompb.TimeSeries {
+	result := []prompb.TimeSeries{
+		c.sample(name+"_sum", base, s.GetSampleSum(), timestampMs),
+		c.sample(name+"_count", base, float64(s.GetSampleCount()), timestampMs),
+	}
+
+	for _, q := range s.GetQuantile() {
+		labels := make([]prompb.Label, 0, len(base)+2)
+		labels = append(labels, prompb.Label{Name: "__name__", Value: name})
+		labels = append(labels, base...)
+		labels = append(labels, prompb.Label{
+			Name:  "quantile",
+			Value: formatFloat(q.GetQuantile()),
+		})
+		result = append(result, prompb.TimeSeries{
+			Labels:  labels,
+			Samples: []prompb.Sample{{Value: q.GetValue(), Timestamp: timestampMs}},
+		})
+	}
+	return result
+}
+
+// histogramSeries expands a histogram into its `_sum`, `_count` and
+// `_bucket`/`le=`-labeled series, including the implicit `+Inf` bucket.
+func (c *remoteWriteClient) histogramSeries(
+	name string,
+	base []prompb.Label,
+	h *dto.Histogram,
+	timestampMs int64,
+) []prompb.TimeSeries {
+	result := []prompb.TimeSeries{
+		c.sample(name+"_sum", base, h.GetSampleSum(), timestampMs),
+		c.sample(name+"_count", base, float64(h.GetSampleCount()), timestampMs),
+	}
+
+	bucketName := name + "_bucket"
+	for _, b := range h.GetBucket() {
+		labels := make([]prompb.Label, 0, len(base)+2)
+		labels = append(labels, prompb.Label{Name: "__name__", Value: bucketName})
+		labels = append(labels, base...)
+		labels = append(labels, prompb.Label{
+			Name:  "le",
+			Value: formatFloat(b.GetUpperBound()),
+		})
+		result = append(result, prompb.TimeSeries{
+			Labels:  labels,
+			Samples: []prompb.Sample{{Value: float64(b.GetCumulativeCount()), Timestamp: timestampMs}},
+		})
+	}
+
+	// The +Inf bucket isn't present in dto.Histogram.Bucket; it's always
+	// equal to the overall sample count.
+	infLabels := make([]prompb.Label, 0, len(base)+2)
+	infLabels = append(infLabels, prompb.Label{Name: "__name__", Value: bucketName})
+	infLabels = append(infLabels, base...)
+	infLabels = append(infLabels, prompb.Label{Name: "le", Value: "+Inf"})
+	result = append(result, prompb.TimeSeries{
+		Labels:  infLabels,
+		Samples: []prompb.Sample{{Value: float64(h.GetSampleCount()), Timestamp: timestampMs}},
+	})
+
+	return result
+}
+
+// formatFloat renders a float the way the Prometheus text exposition
+// format does for label values like `le` and `quantile`.
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
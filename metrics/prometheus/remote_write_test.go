@@ -0,0 +1,152 @@
+package prometheus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gottingen/kmetrics/metrics"
+	prom "github.com/m3db/prometheus_client_golang/prometheus"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+func TestRemoteWriteClientPushesGatheredSamples(t *testing.T) {
+	var received int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		require.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		require.Equal(t, remoteWriteVersion, r.Header.Get(remoteWriteVersionHeader))
+		atomic.AddInt32(&received, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	registry := prom.NewRegistry()
+	counter := prom.NewCounter(prom.CounterOpts{Name: "test_counter", Help: "test"})
+	counter.Inc()
+	require.NoError(t, registry.Register(counter))
+
+	onErrorCalls := make(chan error, 8)
+	client := newRemoteWriteClient(
+		RemoteWriteConfiguration{URL: server.URL, FlushInterval: 10 * time.Millisecond},
+		registry,
+		metrics.NewSanitizer(DefaultSanitizerOpts),
+		func(err error) { onErrorCalls <- err },
+	)
+	client.Start()
+	defer client.Stop()
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&received) > 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestTimeseriesExpandsHistogramBucketsAndCounts(t *testing.T) {
+	registry := prom.NewRegistry()
+	histogram := prom.NewHistogram(prom.HistogramOpts{
+		Name:    "test_histogram",
+		Help:    "test",
+		Buckets: []float64{1, 2, 5},
+	})
+	histogram.Observe(0.5)
+	histogram.Observe(3)
+	require.NoError(t, registry.Register(histogram))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	client := newRemoteWriteClient(
+		RemoteWriteConfiguration{URL: "http://127.0.0.1:0"},
+		registry,
+		metrics.NewSanitizer(DefaultSanitizerOpts),
+		func(err error) {},
+	)
+
+	series := client.timeseries(families)
+
+	names := make(map[string][]prompb.TimeSeries)
+	for _, ts := range series {
+		for _, l := range ts.Labels {
+			if l.Name == "__name__" {
+				names[l.Value] = append(names[l.Value], ts)
+			}
+		}
+	}
+
+	require.Len(t, names["test_histogram_sum"], 1)
+	require.Len(t, names["test_histogram_count"], 1)
+	require.Equal(t, float64(2), names["test_histogram_count"][0].Samples[0].Value)
+	// One bucket series per configured bucket, plus the implicit +Inf bucket.
+	require.Len(t, names["test_histogram_bucket"], 4)
+
+	var sawInf bool
+	for _, ts := range names["test_histogram_bucket"] {
+		for _, l := range ts.Labels {
+			if l.Name == "le" && l.Value == "+Inf" {
+				sawInf = true
+				require.Equal(t, float64(2), ts.Samples[0].Value)
+			}
+		}
+	}
+	require.True(t, sawInf)
+}
+
+func TestTimeseriesExpandsSummaryQuantiles(t *testing.T) {
+	registry := prom.NewRegistry()
+	summary := prom.NewSummary(prom.SummaryOpts{
+		Name:       "test_summary",
+		Help:       "test",
+		Objectives: map[float64]float64{0.5: 0.05, 0.99: 0.001},
+	})
+	summary.Observe(1)
+	summary.Observe(2)
+	require.NoError(t, registry.Register(summary))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	client := newRemoteWriteClient(
+		RemoteWriteConfiguration{URL: "http://127.0.0.1:0"},
+		registry,
+		metrics.NewSanitizer(DefaultSanitizerOpts),
+		func(err error) {},
+	)
+
+	series := client.timeseries(families)
+
+	var sawQuantile bool
+	for _, ts := range series {
+		var name, quantile string
+		for _, l := range ts.Labels {
+			if l.Name == "__name__" {
+				name = l.Value
+			}
+			if l.Name == "quantile" {
+				quantile = l.Value
+			}
+		}
+		if name == "test_summary" && quantile == "0.5" {
+			sawQuantile = true
+		}
+	}
+	require.True(t, sawQuantile)
+}
+
+func TestRemoteWriteClientDropsWhenQueueFull(t *testing.T) {
+	registry := prom.NewRegistry()
+	client := newRemoteWriteClient(
+		RemoteWriteConfiguration{URL: "http://127.0.0.1:0", QueueSize: 1},
+		registry,
+		metrics.NewSanitizer(DefaultSanitizerOpts),
+		func(err error) {},
+	)
+
+	// Fill the bounded queue directly so enqueue has nowhere to put the next push.
+	client.queue <- nil
+	client.enqueue()
+	require.Len(t, client.queue, 1)
+}
@@ -0,0 +1,283 @@
+package prometheus
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gottingen/kmetrics/metrics"
+	prom "github.com/m3db/prometheus_client_golang/prometheus"
+	"github.com/m3db/prometheus_client_golang/prometheus/promhttp"
+)
+
+// DefaultSeparator is the default separator character used to combine
+// parts of a Prometheus metric name. Prometheus doesn't allow "." or "-"
+// in metric names, so scopes using this reporter should set their
+// Separator to this value.
+const DefaultSeparator = "_"
+
+// TimerType determines how timers (and histograms) are represented in
+// Prometheus.
+type TimerType int
+
+const (
+	// SummaryTimerType represents timers as Prometheus summaries.
+	SummaryTimerType TimerType = iota
+
+	// HistogramTimerType represents timers as classic, fixed-bucket
+	// Prometheus histograms.
+	HistogramTimerType
+)
+
+// Options is a set of options for the Prometheus reporter.
+type Options struct {
+	// Registerer is the Prometheus registerer used to register collectors.
+	// Defaults to prom.DefaultRegisterer.
+	Registerer prom.Registerer
+
+	// OnRegisterError is called when registering a metric with Prometheus
+	// fails. Defaults to panicking.
+	OnRegisterError func(err error)
+
+	// DefaultTimerType is the default Prometheus representation for
+	// timers and histograms.
+	DefaultTimerType TimerType
+
+	// DefaultHistogramBuckets are the default classic histogram bucket
+	// upper bounds, used when DefaultTimerType is HistogramTimerType.
+	DefaultHistogramBuckets []float64
+
+	// DefaultSummaryObjectives are the default summary quantile
+	// objectives, used when DefaultTimerType is SummaryTimerType.
+	DefaultSummaryObjectives map[float64]float64
+
+	// CompressResponses, if set, gzip-encodes the HTTPHandler's response
+	// body when the scraper's Accept-Encoding offers gzip.
+	CompressResponses bool
+
+	// Sanitizer sanitizes metric and tag names/values. Defaults to a
+	// sanitizer built from DefaultSanitizerOpts.
+	Sanitizer metrics.Sanitizer
+}
+
+// Reporter is a Prometheus backend for scopes, exposing metrics for
+// scraping (and, via Configuration.RemoteWrite, pushing).
+type Reporter interface {
+	metrics.CachedStatsReporter
+
+	// HTTPHandler serves the registry's exposition format.
+	HTTPHandler() http.Handler
+}
+
+type reporter struct {
+	sync.Mutex
+
+	registerer      prom.Registerer
+	gatherer        prom.Gatherer
+	onRegisterError func(err error)
+	sanitizer       metrics.Sanitizer
+
+	timerType         TimerType
+	histogramBuckets  []float64
+	summaryObjectives map[float64]float64
+
+	handler http.Handler
+
+	counters   map[string]*prom.CounterVec
+	gauges     map[string]*prom.GaugeVec
+	histograms map[string]*prom.HistogramVec
+	summaries  map[string]*prom.SummaryVec
+}
+
+// NewReporter creates a new Prometheus reporter from the given options.
+func NewReporter(opts Options) Reporter {
+	if opts.Registerer == nil {
+		opts.Registerer = prom.DefaultRegisterer
+	}
+	if opts.OnRegisterError == nil {
+		opts.OnRegisterError = func(err error) { panic(err) }
+	}
+	if opts.Sanitizer == nil {
+		opts.Sanitizer = metrics.NewSanitizer(DefaultSanitizerOpts)
+	}
+
+	r := &reporter{
+		registerer:        opts.Registerer,
+		onRegisterError:   opts.OnRegisterError,
+		sanitizer:         opts.Sanitizer,
+		timerType:         opts.DefaultTimerType,
+		histogramBuckets:  opts.DefaultHistogramBuckets,
+		summaryObjectives: opts.DefaultSummaryObjectives,
+		counters:          make(map[string]*prom.CounterVec),
+		gauges:            make(map[string]*prom.GaugeVec),
+		histograms:        make(map[string]*prom.HistogramVec),
+		summaries:         make(map[string]*prom.SummaryVec),
+	}
+
+	if gatherer, ok := opts.Registerer.(prom.Gatherer); ok {
+		r.gatherer = gatherer
+	} else {
+		r.gatherer = prom.DefaultGatherer
+	}
+
+	handler := promhttp.HandlerFor(r.gatherer, promhttp.HandlerOpts{})
+	if opts.CompressResponses {
+		handler = compressHandler(handler)
+	}
+	r.handler = handler
+
+	return r
+}
+
+func (r *reporter) HTTPHandler() http.Handler {
+	return r.handler
+}
+
+func (r *reporter) Capabilities() metrics.Capabilities {
+	return metrics.NewCapabilities(true, true, true)
+}
+
+func (r *reporter) Flush() {}
+
+func (r *reporter) AllocateCounter(name string, tags map[string]string) metrics.CachedCount {
+	vec := r.counterVec(name, tags)
+	return &cachedCounter{counter: vec.With(r.labels(tags))}
+}
+
+func (r *reporter) AllocateGauge(name string, tags map[string]string) metrics.CachedGauge {
+	vec := r.gaugeVec(name, tags)
+	return &cachedGauge{gauge: vec.With(r.labels(tags))}
+}
+
+func (r *reporter) AllocateTimer(name string, tags map[string]string) metrics.CachedTimer {
+	observer := r.observer(name, tags, nil)
+	return &cachedTimer{observer: observer}
+}
+
+func (r *reporter) AllocateHistogram(
+	name string,
+	tags map[string]string,
+	buckets metrics.Buckets,
+) metrics.CachedHistogram {
+	var upperBounds []float64
+	if buckets != nil {
+		upperBounds = buckets.AsValues()
+	}
+	return &cachedHistogram{observer: r.observer(name, tags, upperBounds)}
+}
+
+// observer returns the Prometheus observer backing a histogram or timer
+// metric, honoring the reporter's configured timer type.
+func (r *reporter) observer(
+	name string,
+	tags map[string]string,
+	upperBounds []float64,
+) prom.Observer {
+	if r.timerType == SummaryTimerType {
+		vec := r.summaryVec(name, tags)
+		return vec.With(r.labels(tags))
+	}
+
+	if len(upperBounds) == 0 {
+		upperBounds = r.histogramBuckets
+	}
+
+	opts := prom.HistogramOpts{
+		Name:    r.sanitizer.Name(name),
+		Buckets: upperBounds,
+	}
+
+	vec := r.histogramVec(name, tags, opts)
+	return vec.With(r.labels(tags))
+}
+
+func (r *reporter) counterVec(name string, tags map[string]string) *prom.CounterVec {
+	r.Lock()
+	defer r.Unlock()
+
+	vec, ok := r.counters[name]
+	if !ok {
+		vec = prom.NewCounterVec(prom.CounterOpts{
+			Name: r.sanitizer.Name(name),
+		}, r.tagNames(tags))
+		r.register(vec, func(existing prom.Collector) { vec = existing.(*prom.CounterVec) })
+		r.counters[name] = vec
+	}
+	return vec
+}
+
+func (r *reporter) gaugeVec(name string, tags map[string]string) *prom.GaugeVec {
+	r.Lock()
+	defer r.Unlock()
+
+	vec, ok := r.gauges[name]
+	if !ok {
+		vec = prom.NewGaugeVec(prom.GaugeOpts{
+			Name: r.sanitizer.Name(name),
+		}, r.tagNames(tags))
+		r.register(vec, func(existing prom.Collector) { vec = existing.(*prom.GaugeVec) })
+		r.gauges[name] = vec
+	}
+	return vec
+}
+
+func (r *reporter) summaryVec(name string, tags map[string]string) *prom.SummaryVec {
+	r.Lock()
+	defer r.Unlock()
+
+	vec, ok := r.summaries[name]
+	if !ok {
+		vec = prom.NewSummaryVec(prom.SummaryOpts{
+			Name:       r.sanitizer.Name(name),
+			Objectives: r.summaryObjectives,
+		}, r.tagNames(tags))
+		r.register(vec, func(existing prom.Collector) { vec = existing.(*prom.SummaryVec) })
+		r.summaries[name] = vec
+	}
+	return vec
+}
+
+func (r *reporter) histogramVec(
+	name string,
+	tags map[string]string,
+	opts prom.HistogramOpts,
+) *prom.HistogramVec {
+	r.Lock()
+	defer r.Unlock()
+
+	vec, ok := r.histograms[name]
+	if !ok {
+		vec = prom.NewHistogramVec(opts, r.tagNames(tags))
+		r.register(vec, func(existing prom.Collector) { vec = existing.(*prom.HistogramVec) })
+		r.histograms[name] = vec
+	}
+	return vec
+}
+
+// register registers c, reusing an already-registered collector of the
+// same name (e.g. from an earlier scope sharing this metric) via onExists
+// instead of treating it as a registration error.
+func (r *reporter) register(c prom.Collector, onExists func(existing prom.Collector)) {
+	if err := r.registerer.Register(c); err != nil {
+		if are, ok := err.(prom.AlreadyRegisteredError); ok {
+			onExists(are.ExistingCollector)
+			return
+		}
+		r.onRegisterError(err)
+	}
+}
+
+func (r *reporter) tagNames(tags map[string]string) []string {
+	names := make([]string, 0, len(tags))
+	for k := range tags {
+		names = append(names, r.sanitizer.Key(k))
+	}
+	return names
+}
+
+func (r *reporter) labels(tags map[string]string) prom.Labels {
+	labels := make(prom.Labels, len(tags))
+	for k, v := range tags {
+		labels[r.sanitizer.Key(k)] = r.sanitizer.Value(v)
+	}
+	return labels
+}
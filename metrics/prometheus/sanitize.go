@@ -21,4 +21,24 @@ var (
 		},
 		ReplacementCharacter: metrics.DefaultReplacementCharacter,
 	}
+
+	// OpenMetricsSanitizerOpts produce OpenMetrics/Prometheus-legal metric
+	// names and label keys from arbitrary input, per the OpenMetrics
+	// exposition format grammar.
+	OpenMetricsSanitizerOpts = metrics.SanitizeOptions{
+		ValueCharacters: metrics.ValidCharacters{
+			Ranges:     metrics.AlphanumericRange,
+			Characters: metrics.UnderscoreCharacters,
+		},
+		ReplacementCharacter: metrics.DefaultReplacementCharacter,
+		SanitizeMode:         metrics.OpenMetricsSanitizeMode,
+	}
+
+	// UTF8SanitizerOpts preserve valid UTF-8 names, keys and values
+	// untouched, only escaping invalid bytes as `_u{HEX}_`. Literal
+	// `_u{HEX}_` text in valid input is not escaped, so the result is not
+	// reversible in general.
+	UTF8SanitizerOpts = metrics.SanitizeOptions{
+		SanitizeMode: metrics.UTF8SanitizeMode,
+	}
 )
@@ -118,3 +118,30 @@ type CachedHistogram interface {
 type CachedHistogramBucket interface {
 	ReportSamples(value int64)
 }
+
+// CachedCountExemplar is implemented by CachedCount backends that can
+// attach an OpenMetrics exemplar (e.g. a trace ID label) to a counter
+// increment, for trace-to-metric correlation. It embeds CachedCount so
+// reporters that predate exemplar support keep compiling unmodified;
+// callers should type-assert to this interface and fall back to
+// ReportCount when it isn't implemented.
+type CachedCountExemplar interface {
+	CachedCount
+
+	// ReportCountWithExemplar reports a counter increment annotated with
+	// the given exemplar labels.
+	ReportCountWithExemplar(value int64, labels map[string]string)
+}
+
+// CachedHistogramBucketExemplar is implemented by CachedHistogramBucket
+// backends that can attach an OpenMetrics exemplar to an observation. It
+// embeds CachedHistogramBucket so reporters that predate exemplar support
+// keep compiling unmodified; callers should type-assert to this interface
+// and fall back to ReportSamples when it isn't implemented.
+type CachedHistogramBucketExemplar interface {
+	CachedHistogramBucket
+
+	// ReportSamplesWithExemplar reports histogram samples annotated with
+	// the given exemplar labels.
+	ReportSamplesWithExemplar(value int64, labels map[string]string)
+}
@@ -45,12 +45,35 @@ type ValidCharacters struct {
 	Characters []rune
 }
 
+// SanitizeMode selects which Sanitizer implementation NewSanitizer builds.
+type SanitizeMode int
+
+const (
+	// LegacySanitizeMode replaces any codepoint outside the configured
+	// ranges with a single replacement rune. This is the historical
+	// behavior and remains the default.
+	LegacySanitizeMode SanitizeMode = iota
+
+	// OpenMetricsSanitizeMode enforces the OpenMetrics/Prometheus grammar:
+	// names and keys become `[a-zA-Z_][a-zA-Z0-9_]*` (names may also
+	// contain `:`), prepending a replacement character if the first rune
+	// would otherwise be a digit.
+	OpenMetricsSanitizeMode
+
+	// UTF8SanitizeMode preserves any valid UTF-8 input untouched and
+	// escapes only invalid bytes, using a stable `_u{HEX}_` sequence.
+	// Literal `_u{HEX}_` text in valid input is not escaped, so the
+	// result is not reversible in general.
+	UTF8SanitizeMode
+)
+
 // SanitizeOptions are the set of configurable options for sanitisation.
 type SanitizeOptions struct {
 	NameCharacters       ValidCharacters
 	KeyCharacters        ValidCharacters
 	ValueCharacters      ValidCharacters
 	ReplacementCharacter rune
+	SanitizeMode         SanitizeMode
 }
 
 // Sanitizer sanitizes the provided input based on the function executed.
@@ -67,10 +90,17 @@ type Sanitizer interface {
 
 // NewSanitizer returns a new sanitizer based on provided options.
 func NewSanitizer(opts SanitizeOptions) Sanitizer {
-	return sanitizer{
-		nameFn:  opts.NameCharacters.sanitizeFn(opts.ReplacementCharacter),
-		keyFn:   opts.KeyCharacters.sanitizeFn(opts.ReplacementCharacter),
-		valueFn: opts.ValueCharacters.sanitizeFn(opts.ReplacementCharacter),
+	switch opts.SanitizeMode {
+	case OpenMetricsSanitizeMode:
+		return newOpenMetricsSanitizer(opts)
+	case UTF8SanitizeMode:
+		return newUTF8Sanitizer()
+	default:
+		return sanitizer{
+			nameFn:  opts.NameCharacters.sanitizeFn(opts.ReplacementCharacter),
+			keyFn:   opts.KeyCharacters.sanitizeFn(opts.ReplacementCharacter),
+			valueFn: opts.ValueCharacters.sanitizeFn(opts.ReplacementCharacter),
+		}
 	}
 }
 
@@ -0,0 +1,61 @@
+package metrics
+
+// openMetricsNameRanges are the characters permitted anywhere in an
+// OpenMetrics metric name: letters, digits, underscore and colon.
+var openMetricsNameRanges = ValidCharacters{
+	Ranges:     AlphanumericRange,
+	Characters: []rune{'_', ':'},
+}
+
+// openMetricsKeyRanges are the characters permitted anywhere in an
+// OpenMetrics label key: letters, digits and underscore (no colon).
+var openMetricsKeyRanges = ValidCharacters{
+	Ranges:     AlphanumericRange,
+	Characters: UnderscoreCharacters,
+}
+
+// openMetricsSanitizer produces OpenMetrics/Prometheus-legal names and
+// label keys from arbitrary input: invalid characters are replaced, and a
+// leading digit (otherwise untouched) is prefixed with the replacement
+// character since identifiers may not start with one.
+type openMetricsSanitizer struct {
+	nameFn  SanitizeFn
+	keyFn   SanitizeFn
+	valueFn SanitizeFn
+	repChar rune
+}
+
+func newOpenMetricsSanitizer(opts SanitizeOptions) Sanitizer {
+	repChar := opts.ReplacementCharacter
+	return openMetricsSanitizer{
+		nameFn:  openMetricsNameRanges.sanitizeFn(repChar),
+		keyFn:   openMetricsKeyRanges.sanitizeFn(repChar),
+		valueFn: opts.ValueCharacters.sanitizeFn(repChar),
+		repChar: repChar,
+	}
+}
+
+func (s openMetricsSanitizer) Name(n string) string {
+	return s.prefixIfLeadingDigit(s.nameFn(n))
+}
+
+func (s openMetricsSanitizer) Key(k string) string {
+	return s.prefixIfLeadingDigit(s.keyFn(k))
+}
+
+func (s openMetricsSanitizer) Value(v string) string {
+	return s.valueFn(v)
+}
+
+// prefixIfLeadingDigit prepends the replacement character when the
+// sanitized identifier would otherwise start with a digit, which is
+// disallowed by both the OpenMetrics and Prometheus name grammars.
+func (s openMetricsSanitizer) prefixIfLeadingDigit(sanitized string) string {
+	if len(sanitized) == 0 {
+		return sanitized
+	}
+	if c := sanitized[0]; c >= '0' && c <= '9' {
+		return string(s.repChar) + sanitized
+	}
+	return sanitized
+}
@@ -38,3 +38,49 @@ func TestSanitizeTestCases(t *testing.T) {
 		require.Equal(t, tc.output, fn(tc.input))
 	}
 }
+
+func TestOpenMetricsSanitizerTestCases(t *testing.T) {
+	s := NewSanitizer(SanitizeOptions{
+		ValueCharacters: ValidCharacters{
+			Ranges:     AlphanumericRange,
+			Characters: UnderscoreCharacters,
+		},
+		ReplacementCharacter: DefaultReplacementCharacter,
+		SanitizeMode:         OpenMetricsSanitizeMode,
+	})
+
+	type testCase struct {
+		input  string
+		output string
+	}
+
+	nameCases := []testCase{
+		{"my_metric", "my_metric"},
+		{"my:metric", "my:metric"},
+		{"my-metric", "my_metric"},
+		{"1_metric", "_1_metric"},
+		{"metric name", "metric_name"},
+	}
+	for _, tc := range nameCases {
+		require.Equal(t, tc.output, s.Name(tc.input))
+	}
+
+	keyCases := []testCase{
+		{"my_key", "my_key"},
+		{"my:key", "my_key"},
+		{"2nd_key", "_2nd_key"},
+	}
+	for _, tc := range keyCases {
+		require.Equal(t, tc.output, s.Key(tc.input))
+	}
+}
+
+func TestUTF8SanitizerPreservesValidUTF8(t *testing.T) {
+	s := NewSanitizer(SanitizeOptions{SanitizeMode: UTF8SanitizeMode})
+
+	require.Equal(t, "héllo wörld 世界", s.Value("héllo wörld 世界"))
+	require.Equal(t, "my_metric", s.Name("my_metric"))
+
+	invalid := "abc\xffdef"
+	require.Equal(t, "abc_uFF_def", s.Value(invalid))
+}
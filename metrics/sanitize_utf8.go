@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+)
+
+// utf8Sanitizer passes through any valid UTF-8 string untouched. Invalid
+// bytes are replaced one at a time with a stable `_u{HEX}_` escape. Valid
+// input that already contains such a sequence is left as it is, so it maps
+// to the same output as the invalid bytes it spells; the escaping is
+// therefore not reversible in general.
+type utf8Sanitizer struct{}
+
+func newUTF8Sanitizer() Sanitizer {
+	return utf8Sanitizer{}
+}
+
+func (s utf8Sanitizer) Name(n string) string  { return s.sanitize(n) }
+func (s utf8Sanitizer) Key(k string) string   { return s.sanitize(k) }
+func (s utf8Sanitizer) Value(v string) string { return s.sanitize(v) }
+
+func (s utf8Sanitizer) sanitize(value string) string {
+	if utf8.ValidString(value) {
+		return value
+	}
+
+	var buf bytes.Buffer
+	for i := 0; i < len(value); {
+		r, size := utf8.DecodeRuneInString(value[i:])
+		if r == utf8.RuneError && size <= 1 {
+			fmt.Fprintf(&buf, "_u%02X_", value[i])
+			i++
+			continue
+		}
+		buf.WriteRune(r)
+		i += size
+	}
+	return buf.String()
+}